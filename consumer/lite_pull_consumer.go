@@ -0,0 +1,441 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/internal"
+	"github.com/apache/rocketmq-client-go/internal/backoff"
+	"github.com/apache/rocketmq-client-go/internal/trace"
+	"github.com/apache/rocketmq-client-go/primitive"
+)
+
+// defaultLitePullBatchSize is used when consumerOptions.PullBatchSize is left at
+// its zero value.
+const defaultLitePullBatchSize int32 = 32
+
+// Puller issues a single pull RPC for mq starting at offset, returning the
+// messages received and the offset to resume from on the next call. offset is
+// -1 for a queue that Assign just added and Seek has not repositioned.
+// This package ships no implementation: one must be supplied with WithPuller,
+// which also lets LitePullConsumer's buffering logic be exercised without a
+// live broker.
+type Puller interface {
+	Pull(ctx context.Context, mq primitive.MessageQueue, selector MessageSelector, offset int64, maxNums int32) (msgs []*primitive.MessageExt, nextOffset int64, err error)
+}
+
+// OffsetStore persists the consumed-up-to offset for a message queue.
+// LitePullConsumer uses an in-memory store unless another implementation is
+// supplied with WithOffsetStore; it is not connected to the push consumer's
+// offset store.
+type OffsetStore interface {
+	UpdateOffset(mq primitive.MessageQueue, offset int64) error
+}
+
+// memoryOffsetStore is the OffsetStore used when none is supplied through
+// WithOffsetStore. Offsets committed to it do not survive a process restart.
+type memoryOffsetStore struct {
+	mutex   sync.Mutex
+	offsets map[primitive.MessageQueue]int64
+}
+
+func newMemoryOffsetStore() *memoryOffsetStore {
+	return &memoryOffsetStore{offsets: make(map[primitive.MessageQueue]int64)}
+}
+
+func (s *memoryOffsetStore) UpdateOffset(mq primitive.MessageQueue, offset int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.offsets[mq] = offset
+	return nil
+}
+
+// assignedQueue tracks the prefetch/offset state of one message queue this
+// LitePullConsumer currently owns, either through auto rebalance or Assign.
+type assignedQueue struct {
+	mq             primitive.MessageQueue
+	selector       MessageSelector
+	nextPullOffset int64
+	commitOffset   int64
+}
+
+// LitePullConsumer is a high-level pull consumer. Unlike PullConsumer, it hides
+// the manual PullRequest/queue-offset bookkeeping behind a blocking Poll.
+// Offsets go to the OffsetStore given with WithOffsetStore, or to an in-memory
+// store. PullThresholdForQueue bounds the total number of prefetched messages
+// held across all assigned queues together; PullThresholdSizeForQueue is not
+// applied. Queue assignment is
+// manual only for now, via Assign; see Subscribe for the planned rebalance-backed
+// alternative.
+type LitePullConsumer struct {
+	option consumerOptions
+
+	mutex         sync.Mutex
+	subscriptions map[string]subscriptionState // keyed by namespace-wrapped topic
+	assigned      map[primitive.MessageQueue]*assignedQueue
+	manual        bool // true once Assign has been called; disables auto rebalance
+	buffer        chan *primitive.MessageExt
+	closeOnce     sync.Once
+	done          chan struct{}
+
+	commitWg sync.WaitGroup
+	pullWg   sync.WaitGroup
+}
+
+// NewLitePullConsumer builds a LitePullConsumer from the same Options accepted by
+// the push consumer (WithGroupName, WithNameServer, WithAutoCommit, ...).
+func NewLitePullConsumer(opts ...Option) (*LitePullConsumer, error) {
+	option := defaultPushConsumerOptions()
+	for _, apply := range opts {
+		apply(&option)
+	}
+	if option.GroupName == "" {
+		return nil, fmt.Errorf("consumer group name is blank")
+	}
+	option.GroupName = internal.WrapNamespace(option.Namespace, option.GroupName)
+
+	bufSize := int(option.PullThresholdForQueue)
+	if bufSize <= 0 {
+		bufSize = 1000 // matches PullThresholdForQueue's own documented default
+	}
+
+	c := &LitePullConsumer{
+		option:        option,
+		subscriptions: make(map[string]subscriptionState),
+		assigned:      make(map[primitive.MessageQueue]*assignedQueue),
+		buffer:        make(chan *primitive.MessageExt, bufSize),
+		done:          make(chan struct{}),
+	}
+	return c, nil
+}
+
+// Subscribe records topic and selector for the balanced-consumption model.
+// Subscribe is mutually exclusive with Assign. Queues are meant to be
+// auto-assigned by the shared rebalance service once a subscription exists,
+// but that service is not wired in yet (see the TODO below), so Start
+// currently refuses to start a consumer that has any active subscription
+// instead of silently running one that never pulls a message.
+func (c *LitePullConsumer) Subscribe(topic string, selector MessageSelector) error {
+	if selector.Type == "" {
+		selector.Type = c.option.ExpressionType
+	}
+	if err := selector.validate(); err != nil {
+		return err
+	}
+	if err := checkSQL92Support(selector, c.option.MinBrokerVersion); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.manual {
+		return fmt.Errorf("can not Subscribe after Assign, LitePullConsumer only supports one assignment mode")
+	}
+	topic = internal.WrapNamespace(c.option.Namespace, topic)
+	expressionType, subVersion := selector.SubscriptionFields()
+	// c.option.GroupName is already namespace-wrapped (see NewLitePullConsumer),
+	// so unwrap it first or RetryTopic/DLQTopic would double-wrap the namespace.
+	group := internal.UnwrapNamespace(c.option.Namespace, c.option.GroupName)
+	c.subscriptions[topic] = subscriptionState{
+		selector:       selector,
+		expressionType: expressionType,
+		subVersion:     subVersion,
+		retryTopic:     internal.RetryTopic(c.option.Namespace, group),
+		dlqTopic:       internal.DLQTopic(c.option.Namespace, group),
+	}
+	// TODO: register (topic, selector) with the shared rebalance service so
+	// queues are (re)assigned to this consumer as the cluster topology changes.
+	// expressionType/subVersion above are what that registration's pull-request
+	// and heartbeat encoding must set on the wire for SQL92 filtering to work.
+	return nil
+}
+
+// subscriptionState is what Subscribe records for one topic: the selector the
+// caller asked for, the expressionType/subVersion values derived from it that
+// the pull-request and heartbeat encoding must set on the wire, and the
+// namespaced retry/DLQ topics this consumer group's failed messages land on.
+type subscriptionState struct {
+	selector       MessageSelector
+	expressionType string
+	subVersion     int64
+	retryTopic     string
+	dlqTopic       string
+}
+
+// Assign switches the consumer into manual-assignment mode: it will poll exactly
+// the given queues instead of having them assigned by the rebalance service.
+// Assign is mutually exclusive with Subscribe.
+func (c *LitePullConsumer) Assign(mqs []primitive.MessageQueue) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.manual = true
+	assigned := make(map[primitive.MessageQueue]*assignedQueue, len(mqs))
+	for _, mq := range mqs {
+		if existing, ok := c.assigned[mq]; ok {
+			assigned[mq] = existing
+			continue
+		}
+		assigned[mq] = &assignedQueue{mq: mq, nextPullOffset: -1}
+	}
+	c.assigned = assigned
+	return nil
+}
+
+// Seek repositions the next pull for mq to offset. mq must already be assigned
+// via Assign.
+func (c *LitePullConsumer) Seek(mq primitive.MessageQueue, offset int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	q, ok := c.assigned[mq]
+	if !ok {
+		return fmt.Errorf("message queue %v is not assigned to this consumer", mq)
+	}
+	q.nextPullOffset = offset
+	return nil
+}
+
+// Poll blocks until at least one message is available, timeout elapses, or ctx is
+// done, returning whatever prefetched messages are ready at that point. When a
+// Namespace is configured, MessageExt.Topic is already unwrapped back to its
+// logical, un-prefixed name by the time it reaches the caller.
+func (c *LitePullConsumer) Poll(ctx context.Context, timeout time.Duration) ([]*primitive.MessageExt, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, fmt.Errorf("lite pull consumer has been shutdown")
+	case msg := <-c.buffer:
+		msgs := c.drainBuffered(msg)
+		c.traceDelivery(msgs)
+		return msgs, nil
+	case <-timer.C:
+		return nil, nil
+	}
+}
+
+// traceDelivery emits one trace record per message handed back from Poll, when
+// tracing is enabled. LitePullConsumer has no separate "consume" callback the
+// way the push consumer does, so delivery to the caller is the closest
+// equivalent to a consume-lifecycle event and both timestamps are set to it.
+func (c *LitePullConsumer) traceDelivery(msgs []*primitive.MessageExt) {
+	if c.option.traceDispatcher == nil {
+		return
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, m := range msgs {
+		c.option.traceDispatcher.Append(trace.Context{
+			MsgId:          m.MsgId,
+			Topic:          m.Topic,
+			Tags:           m.Properties["TAGS"],
+			ConsumerGroup:  c.option.GroupName,
+			ConsumeStartTs: now,
+			ConsumeEndTs:   now,
+			Status:         trace.ConsumeSuccess,
+		})
+	}
+}
+
+// drainBuffered collects first, plus anything else already prefetched, without
+// blocking further, so a single Poll call can return a small batch at once.
+func (c *LitePullConsumer) drainBuffered(first *primitive.MessageExt) []*primitive.MessageExt {
+	msgs := []*primitive.MessageExt{first}
+	for {
+		select {
+		case m := <-c.buffer:
+			msgs = append(msgs, m)
+		default:
+			return msgs
+		}
+	}
+}
+
+// Commit persists the consumed-up-to offset for every assigned queue through
+// the configured OffsetStore (WithOffsetStore, or an in-memory store by
+// default). In auto-commit mode (the default, see WithAutoCommit) this runs on
+// AutoCommitInterval and callers do not need to call it themselves.
+func (c *LitePullConsumer) Commit() error {
+	c.mutex.Lock()
+	queues := make([]*assignedQueue, 0, len(c.assigned))
+	for _, q := range c.assigned {
+		queues = append(queues, q)
+	}
+	store := c.option.offsetStore
+	c.mutex.Unlock()
+
+	if store == nil {
+		return nil
+	}
+	for _, q := range queues {
+		if err := store.UpdateOffset(q.mq, q.commitOffset); err != nil {
+			return fmt.Errorf("commit offset for %v: %w", q.mq, err)
+		}
+	}
+	return nil
+}
+
+// Start begins prefetching into the in-memory buffer for every queue given to
+// Assign. Start requires a Puller to have been configured via WithPuller.
+//
+// Automatic, Subscribe-driven assignment via the shared rebalance service is
+// not implemented yet, so Start refuses to start a consumer that has any
+// active subscription - it would otherwise run without error and never pull
+// a single message. Use Assign until that service is wired in here.
+func (c *LitePullConsumer) Start() error {
+	c.mutex.Lock()
+	hasSubscriptions := len(c.subscriptions) > 0
+	c.mutex.Unlock()
+	if hasSubscriptions {
+		return fmt.Errorf("lite pull consumer: Subscribe-based automatic queue assignment is not implemented yet, use Assign instead")
+	}
+	if c.option.puller == nil {
+		return fmt.Errorf("no Puller configured, call WithPuller before Start")
+	}
+	if c.option.offsetStore == nil {
+		c.option.offsetStore = newMemoryOffsetStore()
+	}
+	if c.option.PullBatchSize <= 0 {
+		c.option.PullBatchSize = defaultLitePullBatchSize
+	}
+	if c.option.Trace.Enabled {
+		if c.option.traceSender == nil {
+			return fmt.Errorf("trace is enabled but no Sender configured, call WithTraceSender before Start")
+		}
+		if c.option.Trace.Async {
+			c.option.traceDispatcher = trace.NewDispatcher(c.option.Trace.TraceTopic, c.option.Trace.QueueSize, c.option.traceSender)
+		} else {
+			c.option.traceDispatcher = trace.NewSyncDispatcher(c.option.Trace.TraceTopic, c.option.traceSender)
+		}
+		if err := c.option.traceDispatcher.Start(); err != nil {
+			return fmt.Errorf("start trace dispatcher: %w", err)
+		}
+	}
+
+	if c.option.AutoCommit {
+		c.commitWg.Add(1)
+		go c.autoCommitLoop()
+	}
+
+	c.mutex.Lock()
+	queues := make([]*assignedQueue, 0, len(c.assigned))
+	for _, q := range c.assigned {
+		queues = append(queues, q)
+	}
+	c.mutex.Unlock()
+
+	for _, q := range queues {
+		c.pullWg.Add(1)
+		go c.pullLoop(q)
+	}
+	return nil
+}
+
+// Shutdown stops prefetching and, if auto-commit is enabled, flushes pending offsets.
+func (c *LitePullConsumer) Shutdown() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	c.pullWg.Wait()
+	c.commitWg.Wait()
+	if c.option.traceDispatcher != nil {
+		c.option.traceDispatcher.Shutdown()
+	}
+	if !c.option.AutoCommit {
+		return nil
+	}
+	return c.Commit()
+}
+
+// pullLoop repeatedly pulls q until the consumer is shut down, retrying failed
+// pulls through BackoffPolicy and sleeping PullInterval between empty pulls.
+// With PullInterval at zero, its default, an empty pull is followed by the next
+// pull at once.
+func (c *LitePullConsumer) pullLoop(q *assignedQueue) {
+	defer c.pullWg.Done()
+
+	retrier := backoff.NewRetrier(c.option.BackoffPolicy)
+	for {
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		var msgs []*primitive.MessageExt
+		var nextOffset int64
+		err := retrier.Run(context.Background(), c.done, func() error {
+			var pullErr error
+			msgs, nextOffset, pullErr = c.option.puller.Pull(context.Background(), q.mq, q.selector, q.nextPullOffset, c.option.PullBatchSize)
+			return pullErr
+		})
+		if err != nil {
+			// either the consumer was shut down, or Run gave up because c.done
+			// closed mid-retry; either way there is nothing left to do for q.
+			return
+		}
+
+		q.nextPullOffset = nextOffset
+		q.commitOffset = nextOffset
+
+		for _, m := range msgs {
+			if c.option.Namespace != "" {
+				m.Topic = internal.UnwrapNamespace(c.option.Namespace, m.Topic)
+			}
+			select {
+			case c.buffer <- m:
+			case <-c.done:
+				return
+			}
+		}
+
+		if len(msgs) == 0 {
+			select {
+			case <-time.After(c.option.PullInterval):
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
+
+func (c *LitePullConsumer) autoCommitLoop() {
+	defer c.commitWg.Done()
+
+	interval := c.option.AutoCommitInterval
+	if interval <= 0 {
+		interval = defaultAutoCommitInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = c.Commit()
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,309 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/internal/trace"
+	"github.com/apache/rocketmq-client-go/primitive"
+)
+
+// fakeTraceSender records every batch of trace records it is asked to send.
+type fakeTraceSender struct {
+	mutex   sync.Mutex
+	records []trace.Context
+}
+
+func (s *fakeTraceSender) SendTrace(ctx context.Context, traceTopic string, records []trace.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *fakeTraceSender) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.records)
+}
+
+// fakePuller serves a fixed batch of messages on its first call for each
+// queue, then reports no further messages, so pull loops under test settle
+// instead of looping forever.
+type fakePuller struct {
+	mutex  sync.Mutex
+	served map[primitive.MessageQueue]bool
+	msgs   []*primitive.MessageExt
+}
+
+func (p *fakePuller) Pull(ctx context.Context, mq primitive.MessageQueue, selector MessageSelector, offset int64, maxNums int32) ([]*primitive.MessageExt, int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.served[mq] {
+		return nil, offset, nil
+	}
+	// -1 means no position is known yet; start from the queue's first offset.
+	if offset < 0 {
+		offset = 0
+	}
+	p.served[mq] = true
+	return p.msgs, offset + int64(len(p.msgs)), nil
+}
+
+func TestLitePullConsumer_PollDeliversPulledMessages(t *testing.T) {
+	mq := primitive.MessageQueue{Topic: "test-topic", BrokerName: "broker-a", QueueId: 0}
+	puller := &fakePuller{
+		served: make(map[primitive.MessageQueue]bool),
+		msgs:   []*primitive.MessageExt{{}, {}},
+	}
+
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithPuller(puller),
+		WithAutoCommit(false),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Assign([]primitive.MessageQueue{mq}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer c.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msgs, err := c.Poll(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+}
+
+func TestLitePullConsumer_PollReturnsEmptyOnTimeoutWhenNothingPulled(t *testing.T) {
+	mq := primitive.MessageQueue{Topic: "test-topic", BrokerName: "broker-a", QueueId: 0}
+	puller := &fakePuller{served: make(map[primitive.MessageQueue]bool)}
+
+	c, err := NewLitePullConsumer(WithGroupName("test-group"), WithPuller(puller), WithAutoCommit(false))
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Assign([]primitive.MessageQueue{mq}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer c.Shutdown()
+
+	msgs, err := c.Poll(context.Background(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(msgs) != 0 {
+		t.Fatalf("got %d messages, want 0", len(msgs))
+	}
+}
+
+func TestLitePullConsumer_CommitPersistsOffsetThroughOffsetStore(t *testing.T) {
+	mq := primitive.MessageQueue{Topic: "test-topic", BrokerName: "broker-a", QueueId: 0}
+	puller := &fakePuller{
+		served: make(map[primitive.MessageQueue]bool),
+		msgs:   []*primitive.MessageExt{{}, {}, {}},
+	}
+
+	c, err := NewLitePullConsumer(WithGroupName("test-group"), WithPuller(puller), WithAutoCommit(false))
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Assign([]primitive.MessageQueue{mq}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer c.Shutdown()
+
+	if _, err := c.Poll(context.Background(), time.Second); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if err := c.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	store, ok := c.option.offsetStore.(*memoryOffsetStore)
+	if !ok {
+		t.Fatalf("offsetStore is %T, want *memoryOffsetStore", c.option.offsetStore)
+	}
+	store.mutex.Lock()
+	got, ok := store.offsets[mq]
+	store.mutex.Unlock()
+	if !ok {
+		t.Fatal("offset for assigned queue was never committed")
+	}
+	if got != 3 {
+		t.Fatalf("committed offset = %d, want 3", got)
+	}
+}
+
+func TestLitePullConsumer_PollUnwrapsNamespaceFromTopic(t *testing.T) {
+	mq := primitive.MessageQueue{Topic: "tenant-a%test-topic", BrokerName: "broker-a", QueueId: 0}
+	puller := &fakePuller{
+		served: make(map[primitive.MessageQueue]bool),
+		msgs:   []*primitive.MessageExt{{Topic: "tenant-a%test-topic"}},
+	}
+
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithNamespace("tenant-a"),
+		WithPuller(puller),
+		WithAutoCommit(false),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Assign([]primitive.MessageQueue{mq}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer c.Shutdown()
+
+	msgs, err := c.Poll(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	if got := msgs[0].Topic; got != "test-topic" {
+		t.Fatalf("Topic = %q, want the namespace-unwrapped %q", got, "test-topic")
+	}
+}
+
+func TestLitePullConsumer_SubscribeRecordsNamespacedRetryAndDLQTopics(t *testing.T) {
+	puller := &fakePuller{served: make(map[primitive.MessageQueue]bool)}
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithNamespace("tenant-a"),
+		WithPuller(puller),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Subscribe("test-topic", MessageSelector{Type: TAG, Expression: "*"}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	state, ok := c.subscriptions["tenant-a%test-topic"]
+	if !ok {
+		t.Fatal("subscription was not recorded under its namespace-wrapped topic")
+	}
+	if want := "tenant-a%%RETRY%test-group"; state.retryTopic != want {
+		t.Fatalf("retryTopic = %q, want %q", state.retryTopic, want)
+	}
+	if want := "tenant-a%%DLQ%test-group"; state.dlqTopic != want {
+		t.Fatalf("dlqTopic = %q, want %q", state.dlqTopic, want)
+	}
+}
+
+func TestLitePullConsumer_StartRejectsSubscribeBasedAssignment(t *testing.T) {
+	puller := &fakePuller{served: make(map[primitive.MessageQueue]bool)}
+	c, err := NewLitePullConsumer(WithGroupName("test-group"), WithPuller(puller))
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Subscribe("test-topic", MessageSelector{Type: TAG, Expression: "*"}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	if err := c.Start(); err == nil {
+		t.Fatal("Start with a Subscribe-only consumer should fail instead of silently pulling nothing, forever")
+	}
+}
+
+func TestLitePullConsumer_StartRequiresPuller(t *testing.T) {
+	c, err := NewLitePullConsumer(WithGroupName("test-group"))
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Start(); err == nil {
+		t.Fatal("Start with no Puller configured should return an error")
+	}
+}
+
+func TestLitePullConsumer_StartRequiresTraceSenderWhenTraceEnabled(t *testing.T) {
+	puller := &fakePuller{served: make(map[primitive.MessageQueue]bool)}
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithPuller(puller),
+		WithTrace(TraceConfig{Enabled: true}),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Start(); err == nil {
+		t.Fatal("Start with tracing enabled but no Sender configured should return an error")
+	}
+}
+
+func TestLitePullConsumer_PollEmitsTraceRecordForDeliveredMessage(t *testing.T) {
+	mq := primitive.MessageQueue{Topic: "test-topic", BrokerName: "broker-a", QueueId: 0}
+	puller := &fakePuller{
+		served: make(map[primitive.MessageQueue]bool),
+		msgs:   []*primitive.MessageExt{{MsgId: "msg-1"}},
+	}
+	sender := &fakeTraceSender{}
+
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithPuller(puller),
+		WithAutoCommit(false),
+		WithTrace(TraceConfig{Enabled: true, QueueSize: 16}),
+		WithTraceSender(sender),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+	if err := c.Assign([]primitive.MessageQueue{mq}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if _, err := c.Poll(context.Background(), time.Second); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if got := sender.count(); got != 1 {
+		t.Fatalf("trace sender received %d records, want 1", got)
+	}
+}
@@ -21,9 +21,39 @@ import (
 	"time"
 
 	"github.com/apache/rocketmq-client-go/internal"
+	"github.com/apache/rocketmq-client-go/internal/backoff"
+	"github.com/apache/rocketmq-client-go/internal/trace"
 	"github.com/apache/rocketmq-client-go/primitive"
 )
 
+// defaultTraceTopic is the topic consume-lifecycle trace records are published to
+// when TraceConfig.TraceTopic is left empty.
+const defaultTraceTopic = "RMQ_SYS_TRACE_TOPIC"
+
+// defaultAutoCommitInterval is how often LitePullConsumer commits offsets when
+// auto-commit is enabled and WithAutoCommitInterval was not used to override it.
+const defaultAutoCommitInterval = 5 * time.Second
+
+// TraceConfig controls the optional trace dispatcher that publishes consume-lifecycle
+// records (msgId, topic, tags, timings, status, retry count) for observability.
+type TraceConfig struct {
+	// TraceTopic is the topic trace records are published to. Defaults to
+	// RMQ_SYS_TRACE_TOPIC when empty.
+	TraceTopic string
+
+	// Enabled turns the trace dispatcher on. Tracing is off by default.
+	Enabled bool
+
+	// Async publishes trace records through the batching dispatcher instead of
+	// sending them inline. WithTrace stores the TraceConfig as given, so Async is
+	// false, and records are sent inline, unless the caller sets it.
+	Async bool
+
+	// QueueSize bounds how many pending trace records may be buffered before new
+	// ones are dropped rather than blocking consumption. Defaults to 2048.
+	QueueSize int
+}
+
 type consumerOptions struct {
 	internal.ClientOptions
 
@@ -90,6 +120,11 @@ type consumerOptions struct {
 	// Suspending pulling time for cases requiring slow pulling like flow-control scenario.
 	SuspendCurrentQueueTimeMillis time.Duration
 
+	// BackoffPolicy governs the delay between retries of the pull, rebalance and
+	// broker-reconnect loops, in place of the fixed SuspendCurrentQueueTimeMillis/
+	// PullInterval sleeps. Defaults to an ExponentialBackoff with full jitter.
+	BackoffPolicy backoff.Policy
+
 	// Maximum amount of time a message may block the consuming thread.
 	ConsumeTimeout time.Duration
 
@@ -98,14 +133,81 @@ type consumerOptions struct {
 	ConsumeOrderly bool
 	FromWhere      ConsumeFromWhere
 
+	// ExpressionType is the default expression grammar used for subscriptions that
+	// build their MessageSelector from a bare tag expression instead of setting
+	// MessageSelector.Type explicitly.
+	ExpressionType ExpressionType
+
+	// MinBrokerVersion is the broker version Subscribe assumes it is talking to
+	// when validating a SQL92 MessageSelector, since the client does not learn
+	// the real broker version until it connects. Override it with
+	// WithMinBrokerVersion once that version is known, to fail fast instead of
+	// discovering an unsupported broker only after messages stop matching.
+	MinBrokerVersion int32
+
 	Interceptors []primitive.Interceptor
-	// TODO traceDispatcher
+
+	Trace TraceConfig
+
+	// traceSender delivers batched trace records to the broker once tracing is
+	// enabled. Set via WithTraceSender; there is no usable default because it
+	// always requires a live producer connection.
+	traceSender trace.Sender
+
+	traceDispatcher trace.Dispatcher
+
+	// AutoCommit enables interval-driven offset commit for LitePullConsumer.
+	// When false, the caller must call LitePullConsumer.Commit explicitly.
+	AutoCommit bool
+
+	// AutoCommitInterval is how often LitePullConsumer commits offsets when
+	// AutoCommit is enabled. Defaults to 5s.
+	AutoCommitInterval time.Duration
+
+	// puller issues the pull RPC for LitePullConsumer. Set via WithPuller; there
+	// is no usable default because it always requires a live broker connection.
+	puller Puller
+
+	// offsetStore persists LitePullConsumer's committed offsets. Set via
+	// WithOffsetStore; defaults to an in-memory store when left nil.
+	offsetStore OffsetStore
+
+	// MachineRoom is this consumer's own machine room (data center/AZ) name.
+	// It is stored only: nothing in this package reads it, and
+	// AllocateByMachineRoomNearby takes its resolvers as arguments instead.
+	MachineRoom string
+
+	// ConsumerMachineRoomResolver maps any consumer's client ID in the group to
+	// its machine room. It is stored only; pass the resolver to
+	// AllocateByMachineRoomNearby directly for it to take effect.
+	ConsumerMachineRoomResolver ConsumerMachineRoomResolver
+
+	// BrokerMachineRoomResolver maps a broker name to its machine room. It is
+	// stored only; pass the resolver to AllocateByMachineRoomNearby directly for
+	// it to take effect.
+	BrokerMachineRoomResolver BrokerMachineRoomResolver
+
+	// Namespace isolates this consumer's group, subscribed topics, retry topics
+	// and DLQ topics from other tenants sharing the same cluster: each is sent to
+	// the broker as "namespace%name" and unwrapped back to the logical name on
+	// messages handed to user callbacks. Empty disables namespacing.
+	Namespace string
 }
 
 func defaultPushConsumerOptions() consumerOptions {
 	opts := consumerOptions{
-		ClientOptions: internal.DefaultClientOptions(),
-		Strategy:      AllocateByAveragely,
+		ClientOptions:    internal.DefaultClientOptions(),
+		Strategy:         AllocateByAveragely,
+		ExpressionType:   TAG,
+		MinBrokerVersion: sql92SupportedSince,
+		BackoffPolicy:    backoff.NewExponentialBackoff(100*time.Millisecond, time.Minute),
+		Trace: TraceConfig{
+			TraceTopic: defaultTraceTopic,
+			Async:      true,
+			QueueSize:  2048,
+		},
+		AutoCommit:         true,
+		AutoCommitInterval: defaultAutoCommitInterval,
 	}
 	opts.ClientOptions.GroupName = "DEFAULT_CONSUMER"
 	return opts
@@ -174,3 +276,137 @@ func WithRetry(retries int) Option {
 		opts.RetryTimes = retries
 	}
 }
+
+// WithExpressionType sets the default ExpressionType applied to subscriptions that
+// do not set MessageSelector.Type explicitly. Most applications never need this and
+// can set MessageSelector.Type per-subscription instead.
+func WithExpressionType(t ExpressionType) Option {
+	return func(opts *consumerOptions) {
+		opts.ExpressionType = t
+	}
+}
+
+// WithMinBrokerVersion overrides the broker version Subscribe assumes when
+// validating a SQL92 MessageSelector.
+func WithMinBrokerVersion(v int32) Option {
+	return func(opts *consumerOptions) {
+		opts.MinBrokerVersion = v
+	}
+}
+
+// WithBackoffPolicy sets the retry-delay policy used by the pull, rebalance and
+// broker-reconnect loops, overriding the default ExponentialBackoff.
+func WithBackoffPolicy(p backoff.Policy) Option {
+	return func(opts *consumerOptions) {
+		if p == nil {
+			return
+		}
+		opts.BackoffPolicy = p
+	}
+}
+
+// WithAutoCommit toggles LitePullConsumer's interval-driven offset commit.
+// Disable it to call LitePullConsumer.Commit explicitly instead.
+func WithAutoCommit(enable bool) Option {
+	return func(opts *consumerOptions) {
+		opts.AutoCommit = enable
+	}
+}
+
+// WithAutoCommitInterval sets how often LitePullConsumer commits offsets when
+// auto-commit is enabled.
+func WithAutoCommitInterval(interval time.Duration) Option {
+	return func(opts *consumerOptions) {
+		if interval <= 0 {
+			return
+		}
+		opts.AutoCommitInterval = interval
+	}
+}
+
+// WithNamespace isolates this consumer's group, subscribed topics, retry topics
+// and DLQ topics under ns so multiple logical tenants can share one cluster.
+func WithNamespace(ns string) Option {
+	return func(opts *consumerOptions) {
+		opts.Namespace = ns
+	}
+}
+
+// WithConsumerMachineRoom sets this consumer's own machine room (data center or
+// availability zone) name in consumerOptions.MachineRoom. The value is stored
+// only; AllocateByMachineRoomNearby does not read it.
+func WithConsumerMachineRoom(name string) Option {
+	return func(opts *consumerOptions) {
+		opts.MachineRoom = name
+	}
+}
+
+// WithConsumerMachineRoomResolver stores the function that resolves any
+// consumer's machine room by client ID. AllocateByMachineRoomNearby does not
+// read it from the options; it takes the resolver as an argument.
+func WithConsumerMachineRoomResolver(resolver ConsumerMachineRoomResolver) Option {
+	return func(opts *consumerOptions) {
+		opts.ConsumerMachineRoomResolver = resolver
+	}
+}
+
+// WithBrokerMachineRoomResolver stores the function that resolves a broker's
+// machine room. AllocateByMachineRoomNearby does not read it from the options;
+// it takes the resolver as an argument.
+func WithBrokerMachineRoomResolver(resolver BrokerMachineRoomResolver) Option {
+	return func(opts *consumerOptions) {
+		opts.BrokerMachineRoomResolver = resolver
+	}
+}
+
+// WithAllocateStrategy registers the strategy used to assign message queues to
+// the consumers of a group during rebalance, overriding the default
+// AllocateByAveragely.
+func WithAllocateStrategy(strategy AllocateStrategy) Option {
+	return func(opts *consumerOptions) {
+		if strategy == nil {
+			return
+		}
+		opts.Strategy = strategy
+	}
+}
+
+// WithPuller sets the Puller LitePullConsumer uses to fetch messages from the
+// broker. Required before calling LitePullConsumer.Start.
+func WithPuller(p Puller) Option {
+	return func(opts *consumerOptions) {
+		opts.puller = p
+	}
+}
+
+// WithOffsetStore overrides the OffsetStore LitePullConsumer commits offsets
+// to, in place of the default in-memory store.
+func WithOffsetStore(s OffsetStore) Option {
+	return func(opts *consumerOptions) {
+		opts.offsetStore = s
+	}
+}
+
+// WithTrace configures the trace dispatcher, which publishes consume-lifecycle
+// records to cfg.TraceTopic for observability: batched in the background when
+// cfg.Async is set, inline otherwise. A Sender must
+// also be supplied with WithTraceSender, or Start returns an error.
+func WithTrace(cfg TraceConfig) Option {
+	return func(opts *consumerOptions) {
+		if cfg.TraceTopic == "" {
+			cfg.TraceTopic = defaultTraceTopic
+		}
+		if cfg.QueueSize <= 0 {
+			cfg.QueueSize = 2048
+		}
+		opts.Trace = cfg
+	}
+}
+
+// WithTraceSender sets the Sender the trace dispatcher uses to deliver batched
+// records once WithTrace(TraceConfig{Enabled: true}) is set.
+func WithTraceSender(sender trace.Sender) Option {
+	return func(opts *consumerOptions) {
+		opts.traceSender = sender
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExpressionType is the grammar used to evaluate a MessageSelector's Expression.
+type ExpressionType string
+
+const (
+	// TAG filters messages by an exact match (or OR'd set) of tags, hashed on the client
+	// and matched on the broker. This is the default and is supported by every broker version.
+	TAG = ExpressionType("TAG")
+
+	// SQL92 filters messages against message properties using a subset of SQL92:
+	// AND/OR/NOT, comparisons (>, >=, <, <=, =), BETWEEN...AND, IN (...) and IS [NOT] NULL,
+	// plus boolean literals. Evaluation happens on the broker, so it requires a broker built
+	// with SQL92 filter support enabled.
+	SQL92 = ExpressionType("SQL92")
+)
+
+// IsTagType reports whether exp identifies tag-based filtering, treating the empty
+// string as TAG for backwards compatibility with callers that only ever set Expression.
+func IsTagType(exp ExpressionType) bool {
+	return exp == "" || exp == TAG
+}
+
+// MessageSelector describes how a consumer filters messages for a subscription.
+type MessageSelector struct {
+	// Type is the expression grammar used to evaluate Expression. When left
+	// empty, LitePullConsumer.Subscribe applies the consumer's ExpressionType
+	// option (TAG unless WithExpressionType changed it); elsewhere it means TAG.
+	Type ExpressionType
+
+	// Expression is evaluated according to Type. For TAG it is either "*" (all tags)
+	// or a set of tags separated by "||". For SQL92 it is a boolean SQL92 expression
+	// evaluated against the message's user properties.
+	Expression string
+}
+
+// validate checks that the selector is well-formed before it is sent to the broker,
+// so obvious mistakes fail fast on the client instead of silently matching nothing.
+func (s MessageSelector) validate() error {
+	if s.Expression == "" {
+		return nil
+	}
+	switch s.Type {
+	case "", TAG, SQL92:
+		return nil
+	default:
+		return fmt.Errorf("unsupported expression type: %s, only TAG and SQL92 are supported", s.Type)
+	}
+}
+
+// v4.1.0 is the first broker release that understands SQL92 filter expressions;
+// brokers older than that only honour TAG filtering.
+const sql92SupportedSince = 401
+
+// checkSQL92Support returns an error if selector requires SQL92 filtering but the
+// target broker's version predates support for it, so Subscribe can fail fast
+// instead of silently falling back to matching every message.
+func checkSQL92Support(selector MessageSelector, brokerVersion int32) error {
+	if selector.Type != SQL92 {
+		return nil
+	}
+	if brokerVersion < sql92SupportedSince {
+		return fmt.Errorf("the broker version %d does not support SQL92 filter expressions, "+
+			"upgrade the broker or use TAG filtering instead", brokerVersion)
+	}
+	return nil
+}
+
+// SubscriptionFields returns the expressionType and subVersion the pull request
+// and heartbeat encoding must set on the wire so the broker actually applies
+// this selector server-side, instead of falling back to matching every message.
+// subVersion is the time, in milliseconds, the subscription was (re)established;
+// the broker uses it to detect and reject a stale subscription left over from
+// before a consumer changed its filter.
+func (s MessageSelector) SubscriptionFields() (expressionType string, subVersion int64) {
+	t := s.Type
+	if t == "" {
+		t = TAG
+	}
+	return string(t), time.Now().UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,78 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/apache/rocketmq-client-go/primitive"
+)
+
+func TestCheckSQL92Support(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      MessageSelector
+		brokerVersion int32
+		wantErr       bool
+	}{
+		{"tag selector is always fine", MessageSelector{Type: TAG, Expression: "*"}, 0, false},
+		{"empty type is always fine", MessageSelector{Expression: "*"}, 0, false},
+		{"sql92 on a modern broker", MessageSelector{Type: SQL92, Expression: "a > 1"}, sql92SupportedSince, false},
+		{"sql92 on an old broker", MessageSelector{Type: SQL92, Expression: "a > 1"}, sql92SupportedSince - 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSQL92Support(tt.selector, tt.brokerVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkSQL92Support() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscribe_RejectsSQL92OnUnsupportedBroker(t *testing.T) {
+	puller := &fakePuller{served: make(map[primitive.MessageQueue]bool)}
+	c, err := NewLitePullConsumer(
+		WithGroupName("test-group"),
+		WithPuller(puller),
+		WithMinBrokerVersion(sql92SupportedSince-1),
+	)
+	if err != nil {
+		t.Fatalf("NewLitePullConsumer: %v", err)
+	}
+
+	err = c.Subscribe("test-topic", MessageSelector{Type: SQL92, Expression: "a > 1"})
+	if err == nil {
+		t.Fatal("Subscribe with a SQL92 selector against an unsupported broker version should fail")
+	}
+}
+
+func TestMessageSelector_SubscriptionFields(t *testing.T) {
+	expressionType, subVersion := MessageSelector{Type: SQL92, Expression: "a > 1"}.SubscriptionFields()
+	if expressionType != "SQL92" {
+		t.Fatalf("expressionType = %q, want SQL92", expressionType)
+	}
+	if subVersion <= 0 {
+		t.Fatalf("subVersion = %d, want a positive timestamp", subVersion)
+	}
+
+	expressionType, _ = MessageSelector{}.SubscriptionFields()
+	if expressionType != "TAG" {
+		t.Fatalf("expressionType for an unset Type = %q, want TAG", expressionType)
+	}
+}
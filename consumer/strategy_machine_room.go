@@ -0,0 +1,106 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"sort"
+
+	"github.com/apache/rocketmq-client-go/primitive"
+)
+
+// BrokerMachineRoomResolver maps a broker name to the machine room (data center
+// or availability zone) it physically resides in.
+type BrokerMachineRoomResolver func(brokerName string) string
+
+// ConsumerMachineRoomResolver maps a consumer's client ID to the machine room
+// it is deployed in. AllocateByMachineRoomNearby needs this - not just the
+// current consumer's own room - because every member of the group has to
+// agree on which of its *peers* count as near/far in order to agree on who
+// the donors and round-robin recipients are; a consumer cannot make that call
+// on a peer's behalf using its own room.
+type ConsumerMachineRoomResolver func(cid string) string
+
+// AllocateByMachineRoomNearby wraps inner so that, all else equal, a consumer
+// keeps the message queues whose broker is in its own machine room instead of
+// queues owned by a broker in a different one, cutting down on cross-room
+// traffic. Every consumer's near/far split is evaluated against that
+// consumer's own room via resolveConsumerMachineRoom, so the group can be
+// spread across any number of distinct rooms and every member still agrees on
+// the same near/far, donor and round-robin classification without talking to
+// each other.
+//
+// inner runs first, for every consumer in cidAll, to produce a baseline
+// assignment. A consumer with at least one near queue (broker in its own
+// machine room) is a "donor": it keeps only its near queues, and its far
+// queues go into one shared pool. That pool is then redistributed round-robin
+// across the consumers that have no near queue at all, in addition to whatever
+// inner already gave them. If no consumer is a donor, or none need queues
+// redistributed to them, inner's assignment for currentCID is returned
+// unchanged.
+func AllocateByMachineRoomNearby(inner AllocateStrategy, resolveConsumerMachineRoom ConsumerMachineRoomResolver, resolveBrokerMachineRoom BrokerMachineRoomResolver) AllocateStrategy {
+	return func(consumerGroup string, currentCID string, mqAll []primitive.MessageQueue, cidAll []string) []primitive.MessageQueue {
+		currentOwn := inner(consumerGroup, currentCID, mqAll, cidAll)
+		if resolveBrokerMachineRoom == nil || resolveConsumerMachineRoom == nil {
+			return currentOwn
+		}
+
+		sortedCIDs := append([]string{}, cidAll...)
+		sort.Strings(sortedCIDs)
+
+		var donatedFar []primitive.MessageQueue
+		var noNearCIDs []string
+		var currentNear []primitive.MessageQueue
+		for _, cid := range sortedCIDs {
+			near, far := partitionByMachineRoom(inner(consumerGroup, cid, mqAll, cidAll), resolveConsumerMachineRoom(cid), resolveBrokerMachineRoom)
+			if cid == currentCID {
+				currentNear = near
+			}
+			if len(near) > 0 {
+				donatedFar = append(donatedFar, far...)
+			} else {
+				noNearCIDs = append(noNearCIDs, cid)
+			}
+		}
+
+		if len(donatedFar) == 0 || len(noNearCIDs) == 0 {
+			return currentOwn
+		}
+		if len(currentNear) > 0 {
+			return currentNear
+		}
+
+		result := append([]primitive.MessageQueue{}, currentOwn...)
+		for i, mq := range donatedFar {
+			if noNearCIDs[i%len(noNearCIDs)] == currentCID {
+				result = append(result, mq)
+			}
+		}
+		return result
+	}
+}
+
+func partitionByMachineRoom(mqs []primitive.MessageQueue, machineRoom string, resolve BrokerMachineRoomResolver) (near, far []primitive.MessageQueue) {
+	for _, mq := range mqs {
+		if resolve(mq.BrokerName) == machineRoom {
+			near = append(near, mq)
+		} else {
+			far = append(far, mq)
+		}
+	}
+	return near, far
+}
@@ -0,0 +1,176 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/apache/rocketmq-client-go/primitive"
+)
+
+// fixedAssignment is an AllocateStrategy fake that returns a canned
+// per-consumer assignment, so tests can drive AllocateByMachineRoomNearby
+// against a known baseline instead of a real balancing algorithm.
+func fixedAssignment(byCID map[string][]primitive.MessageQueue) AllocateStrategy {
+	return func(consumerGroup string, currentCID string, mqAll []primitive.MessageQueue, cidAll []string) []primitive.MessageQueue {
+		return byCID[currentCID]
+	}
+}
+
+func roomByBroker(rooms map[string]string) BrokerMachineRoomResolver {
+	return func(brokerName string) string {
+		return rooms[brokerName]
+	}
+}
+
+func roomByConsumer(rooms map[string]string) ConsumerMachineRoomResolver {
+	return func(cid string) string {
+		return rooms[cid]
+	}
+}
+
+// uniformConsumerRoom returns a ConsumerMachineRoomResolver reporting the same
+// room for every consumer, modeling a single-room deployment.
+func uniformConsumerRoom(room string) ConsumerMachineRoomResolver {
+	return func(cid string) string { return room }
+}
+
+func TestAllocateByMachineRoomNearby_RedistributesFarQueuesInsteadOfDroppingThem(t *testing.T) {
+	// cid-near owns one near queue and one far queue; cid-noNear owns nothing.
+	// The far queue must end up with cid-noNear, not vanish.
+	nearQ := primitive.MessageQueue{Topic: "t", BrokerName: "broker-near", QueueId: 0}
+	farQ := primitive.MessageQueue{Topic: "t", BrokerName: "broker-far", QueueId: 1}
+	mqAll := []primitive.MessageQueue{nearQ, farQ}
+	cidAll := []string{"cid-near", "cid-noNear"}
+
+	inner := fixedAssignment(map[string][]primitive.MessageQueue{
+		"cid-near":   {nearQ, farQ},
+		"cid-noNear": nil,
+	})
+	resolve := roomByBroker(map[string]string{"broker-near": "room-a", "broker-far": "room-b"})
+
+	strategy := AllocateByMachineRoomNearby(inner, uniformConsumerRoom("room-a"), resolve)
+
+	near := strategy("group", "cid-near", mqAll, cidAll)
+	noNear := strategy("group", "cid-noNear", mqAll, cidAll)
+
+	if len(near) != 1 || near[0] != nearQ {
+		t.Fatalf("cid-near = %v, want only its near queue %v", near, nearQ)
+	}
+	if len(noNear) != 1 || noNear[0] != farQ {
+		t.Fatalf("cid-noNear = %v, want the redistributed far queue %v (it must not be dropped)", noNear, farQ)
+	}
+}
+
+func TestAllocateByMachineRoomNearby_SpreadsDonatedQueuesRoundRobin(t *testing.T) {
+	nearQ := primitive.MessageQueue{Topic: "t", BrokerName: "broker-near", QueueId: 0}
+	far1 := primitive.MessageQueue{Topic: "t", BrokerName: "broker-far", QueueId: 1}
+	far2 := primitive.MessageQueue{Topic: "t", BrokerName: "broker-far", QueueId: 2}
+	mqAll := []primitive.MessageQueue{nearQ, far1, far2}
+	cidAll := []string{"cid-a", "cid-b", "cid-c"}
+
+	inner := fixedAssignment(map[string][]primitive.MessageQueue{
+		"cid-a": {nearQ, far1, far2},
+		"cid-b": nil,
+		"cid-c": nil,
+	})
+	resolve := roomByBroker(map[string]string{"broker-near": "room-a", "broker-far": "room-b"})
+
+	strategy := AllocateByMachineRoomNearby(inner, uniformConsumerRoom("room-a"), resolve)
+
+	a := strategy("group", "cid-a", mqAll, cidAll)
+	b := strategy("group", "cid-b", mqAll, cidAll)
+	c := strategy("group", "cid-c", mqAll, cidAll)
+
+	if len(a) != 1 || a[0] != nearQ {
+		t.Fatalf("cid-a = %v, want only its near queue %v", a, nearQ)
+	}
+	if len(b)+len(c) != 2 {
+		t.Fatalf("donated far queues = %d between cid-b and cid-c, want 2 total", len(b)+len(c))
+	}
+	if len(b) != 1 || len(c) != 1 {
+		t.Fatalf("donated far queues split %d/%d between cid-b/cid-c, want 1/1 round robin", len(b), len(c))
+	}
+}
+
+func TestAllocateByMachineRoomNearby_UnchangedWhenNoConsumerHasNearQueue(t *testing.T) {
+	far1 := primitive.MessageQueue{Topic: "t", BrokerName: "broker-far", QueueId: 0}
+	far2 := primitive.MessageQueue{Topic: "t", BrokerName: "broker-far", QueueId: 1}
+	mqAll := []primitive.MessageQueue{far1, far2}
+	cidAll := []string{"cid-a", "cid-b"}
+
+	inner := fixedAssignment(map[string][]primitive.MessageQueue{
+		"cid-a": {far1},
+		"cid-b": {far2},
+	})
+	resolve := roomByBroker(map[string]string{"broker-far": "room-b"})
+
+	strategy := AllocateByMachineRoomNearby(inner, uniformConsumerRoom("room-a"), resolve)
+
+	a := strategy("group", "cid-a", mqAll, cidAll)
+	b := strategy("group", "cid-b", mqAll, cidAll)
+	if len(a) != 1 || a[0] != far1 {
+		t.Fatalf("cid-a = %v, want inner's unchanged assignment %v", a, far1)
+	}
+	if len(b) != 1 || b[0] != far2 {
+		t.Fatalf("cid-b = %v, want inner's unchanged assignment %v", b, far2)
+	}
+}
+
+func TestAllocateByMachineRoomNearby_ConsumersInDifferentRoomsAgreeOnAssignment(t *testing.T) {
+	// cid-x lives in room-a, cid-y lives in room-b. qOrphan's broker is in a
+	// third room nobody lives in, so it must stay exactly where inner put it
+	// (on cid-y) instead of being dropped or duplicated by a consumer that
+	// mis-evaluates cid-y's near/far split against its own room instead of
+	// cid-y's.
+	qa := primitive.MessageQueue{Topic: "t", BrokerName: "broker-a", QueueId: 0}
+	qb := primitive.MessageQueue{Topic: "t", BrokerName: "broker-b", QueueId: 1}
+	qOrphan := primitive.MessageQueue{Topic: "t", BrokerName: "broker-c", QueueId: 2}
+	mqAll := []primitive.MessageQueue{qa, qb, qOrphan}
+	cidAll := []string{"cid-x", "cid-y"}
+
+	inner := fixedAssignment(map[string][]primitive.MessageQueue{
+		"cid-x": {qa},
+		"cid-y": {qb, qOrphan},
+	})
+	resolveBroker := roomByBroker(map[string]string{
+		"broker-a": "room-a",
+		"broker-b": "room-b",
+		"broker-c": "room-c",
+	})
+	resolveConsumer := roomByConsumer(map[string]string{
+		"cid-x": "room-a",
+		"cid-y": "room-b",
+	})
+
+	// Both consumers must build their assignment from the very same strategy
+	// value, exactly as they would in production by sharing one resolver
+	// configuration; two independently-built strategies, each only told its
+	// own caller's room, is the bug this test guards against.
+	strategy := AllocateByMachineRoomNearby(inner, resolveConsumer, resolveBroker)
+
+	x := strategy("group", "cid-x", mqAll, cidAll)
+	y := strategy("group", "cid-y", mqAll, cidAll)
+
+	if len(x) != 1 || x[0] != qa {
+		t.Fatalf("cid-x = %v, want only its near queue %v", x, qa)
+	}
+	if len(y) != 2 || y[0] != qb || y[1] != qOrphan {
+		t.Fatalf("cid-y = %v, want its unchanged assignment %v (qOrphan must not move: both consumers have a near queue, so no consumer receives donated queues)", y, []primitive.MessageQueue{qb, qOrphan})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backoff provides pluggable retry-delay policies shared by the client's
+// pull, rebalance and broker-reconnect loops.
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Policy computes how long to wait before the next retry of a failing operation.
+type Policy interface {
+	// Next returns the delay to wait before retry number retry (0-based: the first
+	// retry after an initial failure is Next(0)).
+	Next(retry int) time.Duration
+
+	// Reset is called after a successful attempt so the next failure starts the
+	// backoff sequence over from its minimum delay.
+	Reset()
+}
+
+// ExponentialBackoff doubles the delay on every retry, up to Max, and applies full
+// jitter so that many clients failing at the same time don't all retry in lockstep.
+type ExponentialBackoff struct {
+	// Min is the delay used for the first retry.
+	Min time.Duration
+
+	// Max caps the delay regardless of how many retries have occurred.
+	Max time.Duration
+
+	// Jitter enables full jitter: the returned delay is chosen uniformly at random
+	// between 0 and the computed exponential delay. Defaults to true when left unset
+	// via NewExponentialBackoff.
+	Jitter bool
+}
+
+// NewExponentialBackoff returns an ExponentialBackoff with jitter enabled.
+func NewExponentialBackoff(min, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Min:    min,
+		Max:    max,
+		Jitter: true,
+	}
+}
+
+// Next implements Policy.
+func (b *ExponentialBackoff) Next(retry int) time.Duration {
+	if retry < 0 {
+		retry = 0
+	}
+	delay := b.Min
+	for i := 0; i < retry; i++ {
+		delay *= 2
+		if delay >= b.Max {
+			delay = b.Max
+			break
+		}
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	if b.Jitter && delay > 0 {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+	return delay
+}
+
+// Reset implements Policy. ExponentialBackoff is stateless, so there is nothing to do.
+func (b *ExponentialBackoff) Reset() {}
+
+// Retrier drives a Policy across repeated attempts of the same operation: it
+// tracks the retry count between failures and resets it on success, so callers
+// don't have to duplicate that bookkeeping in every pull/reconnect loop.
+type Retrier struct {
+	Policy Policy
+
+	retry int
+}
+
+// NewRetrier wraps policy in a Retrier ready to drive Run.
+func NewRetrier(policy Policy) *Retrier {
+	return &Retrier{Policy: policy}
+}
+
+// Run calls op until it succeeds, stop is closed, or ctx is done, sleeping
+// between attempts for the duration Policy.Next reports for the current retry
+// count. Policy.Reset is called after a successful attempt so the next failure
+// starts the backoff sequence over from its minimum delay.
+func (r *Retrier) Run(ctx context.Context, stop <-chan struct{}, op func() error) error {
+	for {
+		err := op()
+		if err == nil {
+			r.Policy.Reset()
+			r.retry = 0
+			return nil
+		}
+
+		delay := r.Policy.Next(r.retry)
+		r.retry++
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-stop:
+			timer.Stop()
+			return err
+		}
+	}
+}
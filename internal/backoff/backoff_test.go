@@ -0,0 +1,121 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff_NextCapsAtMax(t *testing.T) {
+	b := &ExponentialBackoff{Min: time.Millisecond, Max: 10 * time.Millisecond, Jitter: false}
+
+	got := b.Next(0)
+	if got != time.Millisecond {
+		t.Fatalf("Next(0) = %v, want %v", got, time.Millisecond)
+	}
+
+	got = b.Next(10)
+	if got != b.Max {
+		t.Fatalf("Next(10) = %v, want capped at Max %v", got, b.Max)
+	}
+}
+
+func TestExponentialBackoff_JitterNeverExceedsComputedDelay(t *testing.T) {
+	b := NewExponentialBackoff(time.Millisecond, 100*time.Millisecond)
+	computed := 8 * time.Millisecond // Min * 2^3, below Max
+	for i := 0; i < 50; i++ {
+		if d := b.Next(3); d < 0 || d > computed {
+			t.Fatalf("Next(3) = %v out of range [0, %v]", d, computed)
+		}
+	}
+}
+
+// countingPolicy records every retry count it is asked for and every Reset, so
+// tests can assert a Retrier actually drives Policy rather than sitting unused.
+type countingPolicy struct {
+	nextCalls  []int
+	resetCalls int
+}
+
+func (p *countingPolicy) Next(retry int) time.Duration {
+	p.nextCalls = append(p.nextCalls, retry)
+	return time.Millisecond
+}
+
+func (p *countingPolicy) Reset() {
+	p.resetCalls++
+}
+
+func TestRetrier_RunRetriesUntilSuccessThenResets(t *testing.T) {
+	policy := &countingPolicy{}
+	r := NewRetrier(policy)
+
+	attempts := 0
+	err := r.Run(context.Background(), nil, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+	if got := policy.nextCalls; len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Fatalf("Next called with %v, want [0 1]", got)
+	}
+	if policy.resetCalls != 1 {
+		t.Fatalf("resetCalls = %d, want 1", policy.resetCalls)
+	}
+}
+
+func TestRetrier_RunStopsOnStopChannel(t *testing.T) {
+	policy := &countingPolicy{}
+	r := NewRetrier(policy)
+
+	stop := make(chan struct{})
+	close(stop)
+
+	err := r.Run(context.Background(), stop, func() error {
+		return errors.New("always fails")
+	})
+	if err == nil {
+		t.Fatal("expected error when stop is already closed, got nil")
+	}
+}
+
+func TestRetrier_RunStopsOnContextDone(t *testing.T) {
+	policy := &ExponentialBackoff{Min: time.Hour, Max: time.Hour}
+	r := NewRetrier(policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := r.Run(ctx, nil, func() error {
+		return errors.New("always fails")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import "strings"
+
+// namespaceSeparator joins a namespace and the resource name it scopes, matching
+// the "ns%resource" convention used across the RocketMQ ecosystem.
+const namespaceSeparator = "%"
+
+// retryGroupTopicPrefix and dlqGroupTopicPrefix name the per-group topics the
+// broker uses to redeliver messages that failed consumption (retry) or
+// exhausted their retries (dead-letter).
+const (
+	retryGroupTopicPrefix = "%RETRY%"
+	dlqGroupTopicPrefix   = "%DLQ%"
+)
+
+// RetryTopic returns the namespaced retry topic for a consumer group.
+func RetryTopic(ns, group string) string {
+	return WrapNamespace(ns, retryGroupTopicPrefix+group)
+}
+
+// DLQTopic returns the namespaced dead-letter topic for a consumer group.
+func DLQTopic(ns, group string) string {
+	return WrapNamespace(ns, dlqGroupTopicPrefix+group)
+}
+
+// WrapNamespace prefixes resource (a consumer group, topic, retry topic or DLQ
+// topic) with ns so that multiple logical tenants can share one cluster without
+// cross-talk. It is a no-op when ns or resource is empty, or resource is already
+// namespaced with ns.
+func WrapNamespace(ns, resource string) string {
+	if ns == "" || resource == "" {
+		return resource
+	}
+	if strings.HasPrefix(resource, ns+namespaceSeparator) {
+		return resource
+	}
+	return ns + namespaceSeparator + resource
+}
+
+// UnwrapNamespace strips the "ns%" prefix added by WrapNamespace from an inbound
+// resource name, so e.g. MessageExt.Topic handed to user callbacks is the
+// un-prefixed logical name. It is a no-op when resource isn't namespaced with ns.
+func UnwrapNamespace(ns, resource string) string {
+	if ns == "" {
+		return resource
+	}
+	prefix := ns + namespaceSeparator
+	if strings.HasPrefix(resource, prefix) {
+		return resource[len(prefix):]
+	}
+	return resource
+}
@@ -0,0 +1,69 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import "testing"
+
+func TestWrapUnwrapNamespace(t *testing.T) {
+	tests := []struct {
+		ns, resource, want string
+	}{
+		{"", "topic", "topic"},
+		{"ns", "", ""},
+		{"ns", "topic", "ns%topic"},
+		{"ns", "ns%topic", "ns%topic"}, // already wrapped: no double-wrap
+	}
+	for _, tt := range tests {
+		if got := WrapNamespace(tt.ns, tt.resource); got != tt.want {
+			t.Errorf("WrapNamespace(%q, %q) = %q, want %q", tt.ns, tt.resource, got, tt.want)
+		}
+	}
+
+	unwrapTests := []struct {
+		ns, resource, want string
+	}{
+		{"", "ns%topic", "ns%topic"},
+		{"ns", "ns%topic", "topic"},
+		{"ns", "topic", "topic"},
+		{"other", "ns%topic", "ns%topic"},
+	}
+	for _, tt := range unwrapTests {
+		if got := UnwrapNamespace(tt.ns, tt.resource); got != tt.want {
+			t.Errorf("UnwrapNamespace(%q, %q) = %q, want %q", tt.ns, tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestWrapNamespace_RoundTrip(t *testing.T) {
+	wrapped := WrapNamespace("tenant-a", "orders")
+	if got := UnwrapNamespace("tenant-a", wrapped); got != "orders" {
+		t.Errorf("round trip = %q, want orders", got)
+	}
+}
+
+func TestRetryAndDLQTopic(t *testing.T) {
+	if got, want := RetryTopic("", "my-group"), "%RETRY%my-group"; got != want {
+		t.Errorf("RetryTopic = %q, want %q", got, want)
+	}
+	if got, want := RetryTopic("ns", "my-group"), "ns%%RETRY%my-group"; got != want {
+		t.Errorf("RetryTopic with namespace = %q, want %q", got, want)
+	}
+	if got, want := DLQTopic("ns", "my-group"), "ns%%DLQ%my-group"; got != want {
+		t.Errorf("DLQTopic with namespace = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trace asynchronously publishes consume-lifecycle trace records to a
+// trace topic so that message consumption can be observed end to end without
+// slowing down the consume path itself.
+package trace
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the outcome of a traced consume attempt.
+type Status string
+
+const (
+	ConsumeSuccess Status = "SUCCESS"
+	ConsumeFailed  Status = "FAILED"
+)
+
+// Context carries the fields of a single consume-lifecycle trace record. One
+// Context is emitted per message per consume attempt, begin and end paired by MsgId.
+type Context struct {
+	MsgId          string
+	Topic          string
+	Tags           string
+	StoreHost      string
+	ClientHost     string
+	ConsumerGroup  string
+	ConsumeStartTs int64
+	ConsumeEndTs   int64
+	Status         Status
+	RetryTimes     int
+	CostMs         int64
+}
+
+// Sender delivers a batch of encoded trace records to the trace topic. It is
+// implemented by an internal producer so this package stays independent of the
+// producer package and avoids an import cycle.
+type Sender interface {
+	SendTrace(ctx context.Context, traceTopic string, records []Context) error
+}
+
+// Dispatcher delivers trace records to a Sender. The one NewDispatcher returns
+// batches and flushes them in the background; the one NewSyncDispatcher
+// returns sends each record inline.
+type Dispatcher interface {
+	// Append hands a record over for delivery. The batching dispatcher returns
+	// false, without blocking, if its queue is full, and drops the record; the
+	// inline one blocks for the send and returns false if the send fails.
+	Append(record Context) bool
+
+	Start() error
+	Shutdown()
+}
+
+const (
+	defaultFlushInterval = time.Second
+)
+
+// asyncDispatcher batches records in memory and flushes them to Sender either
+// when the batch reaches queueSize or on every flush interval tick, whichever
+// comes first.
+type asyncDispatcher struct {
+	traceTopic string
+	queueSize  int
+	sender     Sender
+
+	records chan Context
+	closed  chan struct{}
+	wg      sync.WaitGroup
+
+	dropped uint64
+}
+
+// NewDispatcher creates a Dispatcher that publishes to traceTopic via sender,
+// buffering at most queueSize pending records.
+func NewDispatcher(traceTopic string, queueSize int, sender Sender) Dispatcher {
+	if queueSize <= 0 {
+		queueSize = 2048
+	}
+	return &asyncDispatcher{
+		traceTopic: traceTopic,
+		queueSize:  queueSize,
+		sender:     sender,
+		records:    make(chan Context, queueSize),
+		closed:     make(chan struct{}),
+	}
+}
+
+// Append implements Dispatcher.
+func (d *asyncDispatcher) Append(record Context) bool {
+	select {
+	case d.records <- record:
+		return true
+	default:
+		atomic.AddUint64(&d.dropped, 1)
+		return false
+	}
+}
+
+// Dropped returns the number of records dropped so far because the queue was full.
+func (d *asyncDispatcher) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// Start implements Dispatcher.
+func (d *asyncDispatcher) Start() error {
+	d.wg.Add(1)
+	go d.loop()
+	return nil
+}
+
+// Shutdown implements Dispatcher.
+func (d *asyncDispatcher) Shutdown() {
+	close(d.closed)
+	d.wg.Wait()
+}
+
+// defaultSyncSendTimeout bounds how long syncDispatcher.Append can block a
+// caller on a stalled Sender, since unlike asyncDispatcher it has no
+// background goroutine to absorb the wait.
+const defaultSyncSendTimeout = 3 * time.Second
+
+// syncDispatcher sends every trace record to Sender inline on the calling
+// goroutine instead of batching it, used when TraceConfig.Async is false.
+// Append therefore blocks for the duration of the send, up to
+// defaultSyncSendTimeout.
+type syncDispatcher struct {
+	traceTopic string
+	sender     Sender
+}
+
+// NewSyncDispatcher creates a Dispatcher that publishes each record to sender
+// synchronously as Append is called, with no background batching loop.
+func NewSyncDispatcher(traceTopic string, sender Sender) Dispatcher {
+	return &syncDispatcher{traceTopic: traceTopic, sender: sender}
+}
+
+// Append implements Dispatcher.
+func (d *syncDispatcher) Append(record Context) bool {
+	// trace delivery is best-effort: a failure here must never propagate back
+	// to the consumer's consume path, and must never block its caller
+	// indefinitely if the sender stalls.
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSyncSendTimeout)
+	defer cancel()
+	return d.sender.SendTrace(ctx, d.traceTopic, []Context{record}) == nil
+}
+
+// Start implements Dispatcher. There is no background loop to start.
+func (d *syncDispatcher) Start() error { return nil }
+
+// Shutdown implements Dispatcher. There is nothing to drain since Append
+// already sent every record inline.
+func (d *syncDispatcher) Shutdown() {}
+
+func (d *asyncDispatcher) loop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(defaultFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Context, 0, d.queueSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// trace delivery is best-effort: a failure here must never propagate back
+		// to the consumer's consume path.
+		_ = d.sender.SendTrace(context.Background(), d.traceTopic, batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case r := <-d.records:
+			batch = append(batch, r)
+			if len(batch) >= d.queueSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-d.closed:
+			for {
+				select {
+				case r := <-d.records:
+					batch = append(batch, r)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	mutex   sync.Mutex
+	topic   string
+	records []Context
+}
+
+func (s *fakeSender) SendTrace(ctx context.Context, traceTopic string, records []Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.topic = traceTopic
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *fakeSender) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.records)
+}
+
+func TestAsyncDispatcher_FlushesOnShutdown(t *testing.T) {
+	sender := &fakeSender{}
+	d := NewDispatcher("TRACE_TOPIC", 16, sender)
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if ok := d.Append(Context{MsgId: "msg-1", Status: ConsumeSuccess}); !ok {
+		t.Fatal("Append returned false for a record that should have been queued")
+	}
+	if ok := d.Append(Context{MsgId: "msg-2", Status: ConsumeSuccess}); !ok {
+		t.Fatal("Append returned false for a record that should have been queued")
+	}
+
+	d.Shutdown()
+
+	if got := sender.count(); got != 2 {
+		t.Fatalf("sender received %d records, want 2", got)
+	}
+	if sender.topic != "TRACE_TOPIC" {
+		t.Fatalf("sender received traceTopic %q, want TRACE_TOPIC", sender.topic)
+	}
+}
+
+func TestSyncDispatcher_SendsInlineWithoutStartingABackgroundLoop(t *testing.T) {
+	sender := &fakeSender{}
+	d := NewSyncDispatcher("TRACE_TOPIC", sender)
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if ok := d.Append(Context{MsgId: "msg-1", Status: ConsumeSuccess}); !ok {
+		t.Fatal("Append returned false for a record the sender accepted")
+	}
+
+	// No Shutdown call: a sync dispatcher must have already delivered the
+	// record by the time Append returns, unlike the async one.
+	if got := sender.count(); got != 1 {
+		t.Fatalf("sender received %d records before Shutdown, want 1", got)
+	}
+	if sender.topic != "TRACE_TOPIC" {
+		t.Fatalf("sender received traceTopic %q, want TRACE_TOPIC", sender.topic)
+	}
+
+	d.Shutdown()
+}
+
+// stallingSender blocks SendTrace until its ctx is done, modeling a broker
+// that never responds, so tests can assert syncDispatcher bounds the wait
+// instead of hanging its caller forever.
+type stallingSender struct{}
+
+func (stallingSender) SendTrace(ctx context.Context, traceTopic string, records []Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSyncDispatcher_AppendBoundsWaitOnAStalledSender(t *testing.T) {
+	d := NewSyncDispatcher("TRACE_TOPIC", stallingSender{})
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- d.Append(Context{MsgId: "msg-1"})
+	}()
+
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("Append reported success for a record the sender never acknowledged")
+		}
+	case <-time.After(defaultSyncSendTimeout + time.Second):
+		t.Fatal("Append blocked well past defaultSyncSendTimeout instead of giving up on the stalled sender")
+	}
+}
+
+func TestAsyncDispatcher_DropsWhenQueueFull(t *testing.T) {
+	sender := &fakeSender{}
+	d := NewDispatcher("TRACE_TOPIC", 1, sender).(*asyncDispatcher)
+
+	if ok := d.Append(Context{MsgId: "msg-1"}); !ok {
+		t.Fatal("first Append should succeed")
+	}
+	if ok := d.Append(Context{MsgId: "msg-2"}); ok {
+		t.Fatal("Append should report false once the queue is full")
+	}
+	if d.Dropped() != 1 {
+		t.Fatalf("Dropped() = %d, want 1", d.Dropped())
+	}
+}